@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/zignd/hoster/pkg/hoster"
+)
+
+var Version = "dev" // Will be overridden at build time
+
+const defaultHostsPath = "/etc/hosts"
+
+func main() {
+	// Define command line flags
+	hostsPath := flag.String("hosts", defaultHostsPath, "Path to the hosts file")
+	dockerSocket := flag.String("socket", "", "Docker host: a bare Unix socket path, or a full URI (unix://, tcp://, ssh://); when neither this nor --docker-host is set, $DOCKER_HOST is used, then /var/run/docker.sock")
+	dockerHost := flag.String("docker-host", "", "Docker host URI, same as --socket but takes precedence over it")
+	tlsCACert := flag.String("tlscacert", "", "Path to the TLS CA certificate, for tcp:// hosts")
+	tlsCert := flag.String("tlscert", "", "Path to the TLS client certificate, for tcp:// hosts")
+	tlsKey := flag.String("tlskey", "", "Path to the TLS client key, for tcp:// hosts")
+	tlsVerify := flag.Bool("tlsverify", false, "Verify the Docker daemon's TLS certificate; honors $DOCKER_TLS_VERIFY when unset")
+	dnsListen := flag.String("dns-listen", "", "Address for the embedded DNS resolver to listen on (e.g. "+hoster.DefaultDNSListen+"); disabled when empty")
+	dnsTTL := flag.Duration("dns-ttl", hoster.DefaultDNSTTL, "TTL to report for records served by the embedded DNS resolver")
+	ignoreHealth := flag.Bool("ignore-health", false, "Publish entries as soon as a container starts, ignoring its HEALTHCHECK status")
+	enableIPv4 := flag.Bool("ipv4", true, "Publish A-style entries using each container's IPv4 address")
+	enableIPv6 := flag.Bool("ipv6", false, "Publish AAAA-style entries using each container's IPv6 address")
+	networks := flag.String("networks", "", "Comma-separated list of network names to consider; empty means every network")
+	showHelp := flag.Bool("help", false, "Show help message")
+	showVersion := flag.Bool("version", false, "Display version and exit")
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println("hoster version", Version)
+		os.Exit(0)
+	}
+
+	if *showHelp {
+		fmt.Println("Docker Hoster - Automatically manage /etc/hosts entries for Docker containers")
+		fmt.Println()
+		fmt.Println("Usage: hoster [options]")
+		fmt.Println()
+		fmt.Println("Options:")
+		flag.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Example:")
+		fmt.Println("  sudo hoster --hosts /etc/hosts --socket /var/run/docker.sock")
+		os.Exit(0)
+	}
+
+	publishers := []hoster.Publisher{hoster.NewHostsFilePublisher(*hostsPath)}
+
+	var dnsPublisher *hoster.DNSPublisher
+	if *dnsListen != "" {
+		dnsPublisher = hoster.NewDNSPublisher(*dnsListen, *dnsTTL)
+		if err := dnsPublisher.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start DNS resolver: %v\n", err)
+			os.Exit(1)
+		}
+		defer dnsPublisher.Close()
+		publishers = append(publishers, dnsPublisher)
+	}
+
+	dockerOpts := hoster.DockerOptions{
+		Host:      hoster.ResolveDockerHost(*dockerHost, *dockerSocket),
+		TLSCACert: *tlsCACert,
+		TLSCert:   *tlsCert,
+		TLSKey:    *tlsKey,
+		TLSVerify: *tlsVerify,
+	}
+
+	var networkNames []string
+	if *networks != "" {
+		networkNames = strings.Split(*networks, ",")
+		for i, name := range networkNames {
+			networkNames[i] = strings.TrimSpace(name)
+		}
+	}
+
+	clientOpts := hoster.ClientOptions{
+		IgnoreHealth: *ignoreHealth,
+		IPv4:         *enableIPv4,
+		IPv6:         *enableIPv6,
+		Networks:     networkNames,
+	}
+
+	client, err := hoster.NewClient(dockerOpts, clientOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create hoster: %v\n", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	watcher := hoster.NewWatcher(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle signals
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\nReceived shutdown signal...")
+		cancel()
+	}()
+
+	eventsChan, errChan := watcher.Subscribe(ctx)
+	snapshot := make(map[string][]hoster.ContainerAddress)
+
+	applySnapshot := func() {
+		for _, p := range publishers {
+			if err := p.Apply(snapshot); err != nil {
+				fmt.Fprintf(os.Stderr, "Error updating hosts file: %v\n", err)
+			}
+		}
+	}
+
+loop:
+	for {
+		select {
+		case event, ok := <-eventsChan:
+			if !ok {
+				break loop
+			}
+
+			switch event.Action {
+			case hoster.EventContainerUp:
+				snapshot[event.ContainerID] = event.Addresses
+			case hoster.EventContainerDown:
+				delete(snapshot, event.ContainerID)
+			}
+			applySnapshot()
+
+		case err, ok := <-errChan:
+			if ok && err != nil {
+				fmt.Fprintf(os.Stderr, "Error running hoster: %v\n", err)
+				os.Exit(1)
+			}
+
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	// Clean up published entries on exit.
+	for id := range snapshot {
+		delete(snapshot, id)
+	}
+	applySnapshot()
+}
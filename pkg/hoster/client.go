@@ -0,0 +1,325 @@
+package hoster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/cli/cli/connhelper"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+const defaultSocket = "/var/run/docker.sock"
+
+// DockerOptions configures how a Client connects to the Docker (or
+// Docker-compatible, e.g. Podman) daemon it watches.
+type DockerOptions struct {
+	// Host is a Docker host URI: unix:///var/run/docker.sock,
+	// tcp://host:2376, ssh://user@host, or npipe:////./pipe/docker_engine.
+	Host string
+
+	TLSCACert string
+	TLSCert   string
+	TLSKey    string
+	TLSVerify bool
+}
+
+// ResolveDockerHost mirrors the docker CLI's precedence: an explicit
+// dockerHostFlag wins, then socketFlag (accepting either a full Docker host
+// URI or a legacy bare Unix socket path), then $DOCKER_HOST, then the local
+// Unix socket.
+func ResolveDockerHost(dockerHostFlag, socketFlag string) string {
+	if dockerHostFlag != "" {
+		return dockerHostFlag
+	}
+	if socketFlag != "" {
+		if strings.Contains(socketFlag, "://") {
+			return socketFlag
+		}
+		return "unix://" + socketFlag
+	}
+	if env := os.Getenv("DOCKER_HOST"); env != "" {
+		return env
+	}
+	return "unix://" + defaultSocket
+}
+
+// resolveTLSOptions fills in TLS material from DOCKER_CERT_PATH when the
+// caller didn't pass explicit paths, matching the docker CLI's convention of
+// looking for ca.pem/cert.pem/key.pem inside that directory and defaulting
+// tlsverify to on once DOCKER_TLS_VERIFY is set.
+func resolveTLSOptions(opts DockerOptions) DockerOptions {
+	if os.Getenv("DOCKER_TLS_VERIFY") != "" {
+		opts.TLSVerify = true
+	}
+
+	certPath := os.Getenv("DOCKER_CERT_PATH")
+	if certPath == "" {
+		return opts
+	}
+
+	if opts.TLSCACert == "" {
+		opts.TLSCACert = filepath.Join(certPath, "ca.pem")
+	}
+	if opts.TLSCert == "" {
+		opts.TLSCert = filepath.Join(certPath, "cert.pem")
+	}
+	if opts.TLSKey == "" {
+		opts.TLSKey = filepath.Join(certPath, "key.pem")
+	}
+
+	return opts
+}
+
+// newDockerClientOpts translates DockerOptions into client.Opt values,
+// resolving SSH connections through docker/cli's connection helper so
+// ssh:// hosts work the same way they do for `docker -H ssh://...`.
+func newDockerClientOpts(opts DockerOptions) ([]client.Opt, error) {
+	clientOpts := []client.Opt{client.WithAPIVersionNegotiation()}
+
+	if strings.HasPrefix(opts.Host, "ssh://") {
+		helper, err := connhelper.GetConnectionHelper(opts.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up SSH connection to %s: %w", opts.Host, err)
+		}
+		clientOpts = append(clientOpts,
+			client.WithHost(helper.Host),
+			client.WithDialContext(helper.Dialer),
+			client.WithHTTPClient(&http.Client{Transport: &http.Transport{DialContext: helper.Dialer}}),
+		)
+		return clientOpts, nil
+	}
+
+	clientOpts = append(clientOpts, client.WithHost(opts.Host))
+
+	if opts.TLSVerify || opts.TLSCert != "" {
+		clientOpts = append(clientOpts, client.WithTLSClientConfig(opts.TLSCACert, opts.TLSCert, opts.TLSKey))
+	}
+
+	return clientOpts, nil
+}
+
+// ClientOptions controls how a Client decides what to publish for each
+// container: which address families to emit, which networks to consider,
+// and whether to respect HEALTHCHECK status.
+type ClientOptions struct {
+	IgnoreHealth bool
+	IPv4         bool
+	IPv6         bool
+	// Networks restricts which container networks are considered; empty
+	// means every network is considered.
+	Networks []string
+}
+
+// Client wraps a Docker API client and knows how to turn container state
+// into the ContainerAddress entries hoster publishes. The Docker dependency
+// is kept behind client.CommonAPIClient so it can be swapped for a fake in
+// tests, without requiring a running daemon.
+type Client struct {
+	api  client.CommonAPIClient
+	opts ClientOptions
+}
+
+// NewClient connects to the Docker daemon described by dockerOpts.
+func NewClient(dockerOpts DockerOptions, opts ClientOptions) (*Client, error) {
+	clientOpts, err := newDockerClientOpts(resolveTLSOptions(dockerOpts))
+	if err != nil {
+		return nil, err
+	}
+
+	api, err := client.NewClientWithOpts(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+
+	return &Client{api: api, opts: opts}, nil
+}
+
+// networkAllowed reports whether name passes the opts.Networks restriction.
+func (c *Client) networkAllowed(name string) bool {
+	if len(c.opts.Networks) == 0 {
+		return true
+	}
+	for _, allowed := range c.opts.Networks {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Close releases the underlying connection to the Docker daemon.
+func (c *Client) Close() error {
+	return c.api.Close()
+}
+
+// ListContainers returns the containers currently running on the daemon.
+func (c *Client) ListContainers(ctx context.Context) ([]types.Container, error) {
+	containers, err := c.api.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return containers, nil
+}
+
+// Events subscribes to the daemon's event stream.
+func (c *Client) Events(ctx context.Context) (<-chan events.Message, <-chan error) {
+	return c.api.Events(ctx, events.ListOptions{})
+}
+
+// canonicalNetworkName picks the single network whose bare container
+// name/hostname fallback should be published when a container has no
+// hoster.domains override: labelNetwork if one was requested, else the
+// bridge network if the container is attached to it, else the
+// lexicographically first allowed network. Without this, a container
+// attached to several unaliased networks would publish the same bare name
+// pointing at a different IP per network.
+func canonicalNetworkName(networks map[string]*network.EndpointSettings, allowed func(string) bool, labelNetwork string) string {
+	if labelNetwork != "" {
+		return labelNetwork
+	}
+
+	var names []string
+	for name := range networks {
+		if allowed(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	for _, name := range names {
+		if name == "bridge" {
+			return "bridge"
+		}
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// subnetCIDR derives a network's subnet from one of its endpoint's IP and
+// prefix length (e.g. "172.17.0.2", 16 -> "172.17.0.0/16"), or returns ""
+// if either is missing.
+func subnetCIDR(ip string, prefixLen int) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || prefixLen <= 0 {
+		return ""
+	}
+
+	bits := net.IPv6len * 8
+	if v4 := parsed.To4(); v4 != nil {
+		parsed = v4
+		bits = net.IPv4len * 8
+	}
+
+	network := parsed.Mask(net.CIDRMask(prefixLen, bits))
+	return fmt.Sprintf("%s/%d", network, prefixLen)
+}
+
+// ContainerAddresses inspects a container and returns the addresses hoster
+// should publish for it, applying hoster.* label overrides, the configured
+// network and address-family restrictions, and (unless IgnoreHealth is set)
+// withholding them until its HEALTHCHECK reports healthy. A nil result means
+// the container should not be published at all.
+func (c *Client) ContainerAddresses(ctx context.Context, containerID string) ([]ContainerAddress, error) {
+	info, err := c.api.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	containerHostname := info.Config.Hostname
+	containerName := strings.TrimPrefix(info.Name, "/")
+
+	networkNames := make([]string, 0, len(info.NetworkSettings.Networks))
+	for name := range info.NetworkSettings.Networks {
+		networkNames = append(networkNames, name)
+	}
+
+	labelCfg, err := parseContainerLabels(info.Config.Labels, containerTemplateData{
+		Name:     containerName,
+		Hostname: containerHostname,
+		ID:       info.ID,
+		Labels:   info.Config.Labels,
+		Networks: networkNames,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hoster labels for %s: %w", containerName, err)
+	}
+
+	if !labelCfg.enabled {
+		return nil, nil
+	}
+
+	if !c.opts.IgnoreHealth && info.State != nil && info.State.Health != nil && info.State.Health.Status != "healthy" {
+		// Not yet serving traffic, or no longer is: withhold its entries
+		// until the healthcheck reports healthy again.
+		return nil, nil
+	}
+
+	canonicalNetwork := canonicalNetworkName(info.NetworkSettings.Networks, c.networkAllowed, labelCfg.network)
+
+	var result []ContainerAddress
+
+	// Extract IPs and aliases from every considered network. Both bridge and
+	// user-defined networks show up here, so there's no need for a separate
+	// default-bridge case.
+	for networkName, network := range info.NetworkSettings.Networks {
+		if labelCfg.network != "" && networkName != labelCfg.network {
+			continue
+		}
+		if !c.networkAllowed(networkName) {
+			continue
+		}
+
+		var domains []string
+		switch {
+		case labelCfg.domains != nil:
+			domains = append(domains, labelCfg.domains...)
+		case len(network.Aliases) > 0:
+			// Create a set to avoid duplicates
+			domainsSet := make(map[string]bool)
+			for _, alias := range network.Aliases {
+				domainsSet[alias] = true
+			}
+			domainsSet[containerName] = true
+			domainsSet[containerHostname] = true
+
+			// Convert set to slice
+			domains = make([]string, 0, len(domainsSet))
+			for domain := range domainsSet {
+				domains = append(domains, domain)
+			}
+		case networkName == canonicalNetwork:
+			domains = []string{containerName, containerHostname}
+		default:
+			// No aliases and no hoster.domains override: the bare
+			// name/hostname fallback is only published for the canonical
+			// network, so a container on several unaliased networks
+			// doesn't publish the same name pointing at a different IP
+			// per network.
+			continue
+		}
+
+		domains = appendSuffix(domains, labelCfg.suffix)
+
+		if c.opts.IPv4 && network.IPAddress != "" {
+			subnet := subnetCIDR(network.IPAddress, network.IPPrefixLen)
+			result = append(result, ContainerAddress{IP: network.IPAddress, Name: containerName, Domains: domains, Subnet: subnet})
+		}
+		if c.opts.IPv6 && network.GlobalIPv6Address != "" {
+			subnet := subnetCIDR(network.GlobalIPv6Address, network.GlobalIPv6PrefixLen)
+			result = append(result, ContainerAddress{IP: network.GlobalIPv6Address, Name: containerName, Domains: domains, Subnet: subnet})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,228 @@
+package hoster
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// fakeDockerClient implements client.CommonAPIClient, embedding it with a
+// nil value so only the methods Client actually calls need overriding; any
+// other method would panic if exercised, which a passing test never does.
+type fakeDockerClient struct {
+	client.CommonAPIClient
+
+	inspect types.ContainerJSON
+}
+
+func (f *fakeDockerClient) ContainerInspect(ctx context.Context, id string) (types.ContainerJSON, error) {
+	return f.inspect, nil
+}
+
+func newTestClient(inspect types.ContainerJSON, opts ClientOptions) *Client {
+	return &Client{api: &fakeDockerClient{inspect: inspect}, opts: opts}
+}
+
+func baseInspect() types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:   "abc123",
+			Name: "/web",
+		},
+		Config: &container.Config{
+			Hostname: "web-host",
+			Labels:   map[string]string{},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"bridge": {
+					IPAddress: "172.17.0.2",
+					Aliases:   []string{"web-alias"},
+				},
+			},
+		},
+	}
+}
+
+func TestClient_ContainerAddresses(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(*types.ContainerJSON)
+		wantNil   bool
+		wantCount int
+		// wantDomains, when set, is the set of names every returned
+		// address must carry, in any order.
+		wantDomains []string
+	}{
+		{
+			name:      "default aliases",
+			mutate:    func(*types.ContainerJSON) {},
+			wantCount: 1,
+		},
+		{
+			name: "hoster.enable=false skips the container",
+			mutate: func(j *types.ContainerJSON) {
+				j.Config.Labels["hoster.enable"] = "false"
+			},
+			wantNil: true,
+		},
+		{
+			name: "hoster.domains replaces the alias set",
+			mutate: func(j *types.ContainerJSON) {
+				j.Config.Labels["hoster.domains"] = "api.local,api.dev.local"
+			},
+			wantCount:   1,
+			wantDomains: []string{"api.local", "api.dev.local"},
+		},
+		{
+			name: "hoster.suffix is appended to every name",
+			mutate: func(j *types.ContainerJSON) {
+				j.Config.Labels["hoster.suffix"] = ".docker.test"
+			},
+			wantCount:   1,
+			wantDomains: []string{"web-alias.docker.test", "web.docker.test", "web-host.docker.test"},
+		},
+		{
+			name: "hoster.network restricts to the named network",
+			mutate: func(j *types.ContainerJSON) {
+				j.Config.Labels["hoster.network"] = "does-not-exist"
+			},
+			wantCount: 0,
+		},
+		{
+			name: "unhealthy container is withheld",
+			mutate: func(j *types.ContainerJSON) {
+				j.State = &types.ContainerState{Health: &types.Health{Status: "unhealthy"}}
+			},
+			wantNil: true,
+		},
+		{
+			name: "IPv6 address is ignored unless enabled",
+			mutate: func(j *types.ContainerJSON) {
+				j.NetworkSettings.Networks["bridge"].GlobalIPv6Address = "fd00::2"
+			},
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspect := baseInspect()
+			tt.mutate(&inspect)
+
+			c := newTestClient(inspect, ClientOptions{IPv4: true})
+			addrs, err := c.ContainerAddresses(context.Background(), "abc123")
+			if err != nil {
+				t.Fatalf("ContainerAddresses() error = %v", err)
+			}
+
+			if tt.wantNil && addrs != nil {
+				t.Fatalf("expected nil addresses, got %v", addrs)
+			}
+			if !tt.wantNil && len(addrs) != tt.wantCount {
+				t.Fatalf("expected %d addresses, got %d (%v)", tt.wantCount, len(addrs), addrs)
+			}
+			if tt.wantDomains != nil {
+				want := append([]string(nil), tt.wantDomains...)
+				sort.Strings(want)
+				for _, addr := range addrs {
+					got := append([]string(nil), addr.Domains...)
+					sort.Strings(got)
+					if !reflect.DeepEqual(got, want) {
+						t.Fatalf("expected domains %v, got %v", want, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestClient_ContainerAddresses_IgnoreHealth(t *testing.T) {
+	inspect := baseInspect()
+	inspect.State = &types.ContainerState{Health: &types.Health{Status: "unhealthy"}}
+
+	c := newTestClient(inspect, ClientOptions{IPv4: true, IgnoreHealth: true})
+	addrs, err := c.ContainerAddresses(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("ContainerAddresses() error = %v", err)
+	}
+	if len(addrs) != 1 {
+		t.Fatalf("expected ignoreHealth to publish the container anyway, got %v", addrs)
+	}
+}
+
+func TestClient_ContainerAddresses_IPv6(t *testing.T) {
+	inspect := baseInspect()
+	inspect.NetworkSettings.Networks["bridge"].GlobalIPv6Address = "fd00::2"
+
+	c := newTestClient(inspect, ClientOptions{IPv4: true, IPv6: true})
+	addrs, err := c.ContainerAddresses(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("ContainerAddresses() error = %v", err)
+	}
+	if len(addrs) != 2 {
+		t.Fatalf("expected separate IPv4 and IPv6 entries, got %v", addrs)
+	}
+}
+
+func TestClient_ContainerAddresses_NetworksFilter(t *testing.T) {
+	inspect := baseInspect()
+	inspect.NetworkSettings.Networks["frontend"] = &network.EndpointSettings{IPAddress: "10.0.0.2"}
+
+	c := newTestClient(inspect, ClientOptions{IPv4: true, Networks: []string{"frontend"}})
+	addrs, err := c.ContainerAddresses(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("ContainerAddresses() error = %v", err)
+	}
+	if len(addrs) != 1 || addrs[0].IP != "10.0.0.2" {
+		t.Fatalf("expected only the frontend network's address, got %v", addrs)
+	}
+}
+
+func TestClient_ContainerAddresses_MultipleUnaliasedNetworks(t *testing.T) {
+	inspect := baseInspect()
+	inspect.NetworkSettings.Networks["bridge"].Aliases = nil
+	inspect.NetworkSettings.Networks["frontend"] = &network.EndpointSettings{IPAddress: "10.0.0.5"}
+
+	c := newTestClient(inspect, ClientOptions{IPv4: true})
+	addrs, err := c.ContainerAddresses(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("ContainerAddresses() error = %v", err)
+	}
+
+	if len(addrs) != 1 {
+		t.Fatalf("expected the bare name/hostname fallback to be published for only one network, got %v", addrs)
+	}
+	if addrs[0].IP != "172.17.0.2" {
+		t.Fatalf("expected the bridge network to be picked as canonical, got %v", addrs)
+	}
+}
+
+func TestResolveTLSOptions_TLSVerifyWithoutCertPath(t *testing.T) {
+	t.Setenv("DOCKER_CERT_PATH", "")
+	t.Setenv("DOCKER_TLS_VERIFY", "1")
+
+	opts := resolveTLSOptions(DockerOptions{})
+	if !opts.TLSVerify {
+		t.Fatalf("expected DOCKER_TLS_VERIFY to be honored even without DOCKER_CERT_PATH, got %+v", opts)
+	}
+}
+
+func TestResolveTLSOptions_CertPathFillsInPaths(t *testing.T) {
+	t.Setenv("DOCKER_CERT_PATH", "/certs")
+	t.Setenv("DOCKER_TLS_VERIFY", "")
+
+	opts := resolveTLSOptions(DockerOptions{})
+	if opts.TLSVerify {
+		t.Fatalf("expected TLSVerify to stay false without DOCKER_TLS_VERIFY, got %+v", opts)
+	}
+	if opts.TLSCACert != "/certs/ca.pem" || opts.TLSCert != "/certs/cert.pem" || opts.TLSKey != "/certs/key.pem" {
+		t.Fatalf("expected cert paths derived from DOCKER_CERT_PATH, got %+v", opts)
+	}
+}
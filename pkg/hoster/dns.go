@@ -0,0 +1,225 @@
+package hoster
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DefaultDNSListen and DefaultDNSTTL are sensible defaults for callers
+// wiring up a DNSPublisher from CLI flags.
+const DefaultDNSListen = "127.0.0.53:5354"
+const DefaultDNSTTL = 5 * time.Second
+
+// dnsTable is a forward/reverse lookup table for one view of the snapshot.
+type dnsTable struct {
+	forward map[string][]net.IP // lowercase FQDN -> IPs
+	reverse map[string]string   // reverse zone name -> FQDN
+}
+
+func newDNSTable() dnsTable {
+	return dnsTable{forward: make(map[string][]net.IP), reverse: make(map[string]string)}
+}
+
+// scopedTable is a dnsTable restricted to addresses on one container
+// network, served only to queries whose source address falls inside
+// subnet.
+type scopedTable struct {
+	subnet *net.IPNet
+	table  dnsTable
+}
+
+// dnsView is the read-copy-updated snapshot served by DNSPublisher. Swapping
+// it atomically means in-flight queries never observe a half-built table.
+//
+// Queries are answered from the scoped table of the network the querying
+// client is on, so a container attached to two networks with the same
+// bare name (e.g. "web") doesn't leak the other network's address to a
+// client that can't reach it. merged, which holds every address across
+// every network, is used as a fallback for queries whose source address
+// isn't recognized as belonging to any known container subnet - e.g. the
+// resolver's own host.
+type dnsView struct {
+	scoped []scopedTable
+	merged dnsTable
+}
+
+// tableFor picks the scoped table whose subnet contains addr, or falls back
+// to the merged, all-networks table when addr doesn't match any of them.
+func (v *dnsView) tableFor(addr net.Addr) dnsTable {
+	host := addr.String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		for _, s := range v.scoped {
+			if s.subnet.Contains(ip) {
+				return s.table
+			}
+		}
+	}
+
+	return v.merged
+}
+
+// DNSPublisher runs an in-process authoritative DNS server that answers
+// A/AAAA/PTR queries for every container name, hostname, and network alias
+// tracked by hoster, as an alternative to rewriting /etc/hosts. Names it
+// doesn't own are answered with NXDOMAIN (or SERVFAIL on a malformed query)
+// so the resolver stub can fall through to an upstream server. It scopes
+// answers to the querying client's own container subnet where possible; see
+// dnsView.
+type DNSPublisher struct {
+	addr string
+	ttl  time.Duration
+
+	view   atomic.Value // holds *dnsView
+	server *dns.Server
+}
+
+func NewDNSPublisher(addr string, ttl time.Duration) *DNSPublisher {
+	p := &DNSPublisher{addr: addr, ttl: ttl}
+	p.view.Store(&dnsView{merged: newDNSTable()})
+	return p
+}
+
+// Start launches the DNS server in the background and returns once it is
+// listening, or with an error if it failed to bind.
+func (p *DNSPublisher) Start() error {
+	mux := dns.NewServeMux()
+	mux.HandleFunc(".", p.handleQuery)
+	p.server = &dns.Server{Addr: p.addr, Net: "udp", Handler: mux}
+
+	errCh := make(chan error, 1)
+	p.server.NotifyStartedFunc = func() { errCh <- nil }
+	go func() {
+		if err := p.server.ListenAndServe(); err != nil {
+			errCh <- err
+		}
+	}()
+
+	if err := <-errCh; err != nil {
+		return fmt.Errorf("failed to start DNS server on %s: %w", p.addr, err)
+	}
+	return nil
+}
+
+func (p *DNSPublisher) Close() error {
+	if p.server == nil {
+		return nil
+	}
+	return p.server.Shutdown()
+}
+
+// Apply rebuilds the forward and reverse lookup tables from the current
+// container snapshot and atomically swaps them in.
+func (p *DNSPublisher) Apply(snapshot map[string][]ContainerAddress) error {
+	next := &dnsView{merged: newDNSTable()}
+	bySubnet := make(map[string]dnsTable)
+
+	for _, addresses := range snapshot {
+		for _, addr := range addresses {
+			ip := net.ParseIP(addr.IP)
+			if ip == nil {
+				continue
+			}
+			rev, err := dns.ReverseAddr(addr.IP)
+			if err != nil {
+				continue
+			}
+
+			table, ok := bySubnet[addr.Subnet]
+			if addr.Subnet != "" && !ok {
+				table = newDNSTable()
+				bySubnet[addr.Subnet] = table
+			}
+
+			for _, domain := range addr.Domains {
+				fqdn := dns.Fqdn(strings.ToLower(domain))
+				next.merged.forward[fqdn] = append(next.merged.forward[fqdn], ip)
+				next.merged.reverse[rev] = fqdn
+				if addr.Subnet != "" {
+					table.forward[fqdn] = append(table.forward[fqdn], ip)
+					table.reverse[rev] = fqdn
+				}
+			}
+		}
+	}
+
+	for cidr, table := range bySubnet {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		next.scoped = append(next.scoped, scopedTable{subnet: subnet, table: table})
+	}
+
+	p.view.Store(next)
+	return nil
+}
+
+func (p *DNSPublisher) handleQuery(w dns.ResponseWriter, r *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(r)
+	msg.Authoritative = true
+
+	if len(r.Question) != 1 {
+		msg.Rcode = dns.RcodeServerFailure
+		_ = w.WriteMsg(msg)
+		return
+	}
+
+	view := p.view.Load().(*dnsView)
+	table := view.tableFor(w.RemoteAddr())
+	q := r.Question[0]
+	name := strings.ToLower(q.Name)
+
+	switch q.Qtype {
+	case dns.TypeA, dns.TypeAAAA:
+		ips, ok := table.forward[name]
+		if !ok {
+			msg.Rcode = dns.RcodeNameError
+			break
+		}
+		for _, ip := range ips {
+			if q.Qtype == dns.TypeA && ip.To4() != nil {
+				msg.Answer = append(msg.Answer, &dns.A{
+					Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: uint32(p.ttl.Seconds())},
+					A:   ip.To4(),
+				})
+			}
+			if q.Qtype == dns.TypeAAAA && ip.To4() == nil {
+				msg.Answer = append(msg.Answer, &dns.AAAA{
+					Hdr:  dns.RR_Header{Name: name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: uint32(p.ttl.Seconds())},
+					AAAA: ip,
+				})
+			}
+		}
+		if len(msg.Answer) == 0 {
+			// The name exists but not for this record type, and we have no
+			// upstream of our own to chain to from inside this handler.
+			msg.Rcode = dns.RcodeServerFailure
+		}
+
+	case dns.TypePTR:
+		fqdn, ok := table.reverse[name]
+		if !ok {
+			msg.Rcode = dns.RcodeNameError
+			break
+		}
+		msg.Answer = append(msg.Answer, &dns.PTR{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: uint32(p.ttl.Seconds())},
+			Ptr: fqdn,
+		})
+
+	default:
+		msg.Rcode = dns.RcodeServerFailure
+	}
+
+	_ = w.WriteMsg(msg)
+}
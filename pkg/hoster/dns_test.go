@@ -0,0 +1,46 @@
+package hoster
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDNSPublisher_Apply_ScopesAnswersBySubnet(t *testing.T) {
+	p := NewDNSPublisher(DefaultDNSListen, time.Second)
+
+	err := p.Apply(map[string][]ContainerAddress{
+		"frontend-container": {
+			{IP: "10.0.0.5", Name: "web", Domains: []string{"web."}, Subnet: "10.0.0.0/24"},
+		},
+		"backend-container": {
+			{IP: "10.0.1.5", Name: "web", Domains: []string{"web."}, Subnet: "10.0.1.0/24"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	view := p.view.Load().(*dnsView)
+
+	frontendClient := &net.UDPAddr{IP: net.ParseIP("10.0.0.42")}
+	table := view.tableFor(frontendClient)
+	ips := table.forward["web."]
+	if len(ips) != 1 || !ips[0].Equal(net.ParseIP("10.0.0.5")) {
+		t.Fatalf("expected a client on 10.0.0.0/24 to resolve web. to 10.0.0.5, got %v", ips)
+	}
+
+	backendClient := &net.UDPAddr{IP: net.ParseIP("10.0.1.42")}
+	table = view.tableFor(backendClient)
+	ips = table.forward["web."]
+	if len(ips) != 1 || !ips[0].Equal(net.ParseIP("10.0.1.5")) {
+		t.Fatalf("expected a client on 10.0.1.0/24 to resolve web. to 10.0.1.5, got %v", ips)
+	}
+
+	unknownClient := &net.UDPAddr{IP: net.ParseIP("192.168.9.9")}
+	table = view.tableFor(unknownClient)
+	ips = table.forward["web."]
+	if len(ips) != 2 {
+		t.Fatalf("expected an unrecognized client to fall back to the merged table with both addresses, got %v", ips)
+	}
+}
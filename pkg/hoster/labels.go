@@ -0,0 +1,96 @@
+package hoster
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	labelEnable  = "hoster.enable"
+	labelDomains = "hoster.domains"
+	labelSuffix  = "hoster.suffix"
+	labelNetwork = "hoster.network"
+)
+
+// containerTemplateData is the context available to hoster.domains templates,
+// e.g. {{.Name}}.{{index .Labels "com.docker.compose.project"}}.local
+type containerTemplateData struct {
+	Name     string
+	Hostname string
+	ID       string
+	Labels   map[string]string
+	Networks []string
+}
+
+// containerLabelConfig is the subset of hoster.* labels that customize how a
+// container's names get published.
+type containerLabelConfig struct {
+	enabled bool
+	domains []string // rendered replacement domain list; nil means "use the default aliases"
+	suffix  string
+	network string // restrict publication to this network name; empty means no restriction
+}
+
+// parseContainerLabels reads the hoster.* labels off a container and renders
+// any text/template expressions found in hoster.domains.
+func parseContainerLabels(labels map[string]string, data containerTemplateData) (containerLabelConfig, error) {
+	cfg := containerLabelConfig{enabled: true}
+
+	if v, ok := labels[labelEnable]; ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s label %q: %w", labelEnable, v, err)
+		}
+		cfg.enabled = enabled
+	}
+
+	cfg.suffix = labels[labelSuffix]
+	cfg.network = labels[labelNetwork]
+
+	if raw, ok := labels[labelDomains]; ok && raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			rendered, err := renderDomainTemplate(part, data)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s entry %q: %w", labelDomains, part, err)
+			}
+			cfg.domains = append(cfg.domains, rendered)
+		}
+	}
+
+	return cfg, nil
+}
+
+func renderDomainTemplate(text string, data containerTemplateData) (string, error) {
+	tmpl, err := template.New("domain").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// appendSuffix returns domains with suffix appended to each entry, or
+// domains unchanged when suffix is empty.
+func appendSuffix(domains []string, suffix string) []string {
+	if suffix == "" {
+		return domains
+	}
+
+	suffixed := make([]string, len(domains))
+	for i, domain := range domains {
+		suffixed[i] = domain + suffix
+	}
+	return suffixed
+}
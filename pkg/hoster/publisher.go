@@ -0,0 +1,122 @@
+package hoster
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+const enclosingPattern = "#-----------Docker-Hoster-Domains----------\n"
+
+// Publisher receives the full snapshot of container addresses tracked by a
+// Watcher every time it changes, and is responsible for making that
+// information available through whatever mechanism it implements (a hosts
+// file, a DNS server, a Consul catalog, etc). Third parties can embed hoster
+// and implement Publisher to drive their own sink.
+type Publisher interface {
+	Apply(snapshot map[string][]ContainerAddress) error
+}
+
+// HostsFilePublisher writes container addresses into a system hosts file,
+// replacing everything between enclosingPattern and the end of the file on
+// every update. This is hoster's original behavior.
+type HostsFilePublisher struct {
+	hostsPath string
+}
+
+func NewHostsFilePublisher(hostsPath string) *HostsFilePublisher {
+	return &HostsFilePublisher{hostsPath: hostsPath}
+}
+
+func (p *HostsFilePublisher) Apply(snapshot map[string][]ContainerAddress) error {
+	if len(snapshot) == 0 {
+		fmt.Println("Removing all hosts before exit...")
+	} else {
+		fmt.Println("Updating hosts file with:")
+	}
+
+	addresses := flattenSorted(snapshot)
+	for _, addr := range addresses {
+		fmt.Printf("ip: %s domains: %v\n", addr.IP, addr.Domains)
+	}
+
+	// Read all lines from the original file
+	data, err := os.ReadFile(p.hostsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+
+	// Remove all lines after the known pattern
+	for i, line := range lines {
+		if line+"\n" == enclosingPattern {
+			lines = lines[:i]
+			break
+		}
+	}
+
+	// Remove trailing empty lines
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// Append all domain lines
+	if len(snapshot) > 0 {
+		lines = append(lines, "", strings.TrimSuffix(enclosingPattern, "\n"))
+
+		for _, addr := range addresses {
+			domainsStr := strings.Join(addr.Domains, "   ")
+			lines = append(lines, fmt.Sprintf("%s    %s", addr.IP, domainsStr))
+		}
+
+		lines = append(lines, "#-----Do-not-add-hosts-after-this-line-----", "")
+	}
+
+	// Write to auxiliary file
+	auxFilePath := p.hostsPath + ".aux"
+	content := strings.Join(lines, "\n")
+	if err := os.WriteFile(auxFilePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write aux file: %w", err)
+	}
+
+	// Atomic replace using rename
+	if err := os.Rename(auxFilePath, p.hostsPath); err != nil {
+		return fmt.Errorf("failed to rename aux file: %w", err)
+	}
+
+	return nil
+}
+
+// flattenSorted flattens a snapshot into a single slice ordered by container
+// name, then by IP family (IPv4 before IPv6), then by the IP itself, so the
+// written file is stable across restarts instead of shuffling with Go's
+// randomized map order, even when two entries share both a name and family.
+func flattenSorted(snapshot map[string][]ContainerAddress) []ContainerAddress {
+	var addresses []ContainerAddress
+	for _, addrs := range snapshot {
+		addresses = append(addresses, addrs...)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].Name != addresses[j].Name {
+			return addresses[i].Name < addresses[j].Name
+		}
+		if rankI, rankJ := ipFamilyRank(addresses[i].IP), ipFamilyRank(addresses[j].IP); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return addresses[i].IP < addresses[j].IP
+	})
+
+	return addresses
+}
+
+// ipFamilyRank sorts IPv4 addresses before IPv6.
+func ipFamilyRank(ip string) int {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() != nil {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,25 @@
+package hoster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenSorted_DeterministicOnNameTies(t *testing.T) {
+	snapshot := map[string][]ContainerAddress{
+		"container-a": {{IP: "10.0.0.5", Name: "web", Domains: []string{"web"}}},
+		"container-b": {{IP: "172.17.0.2", Name: "web", Domains: []string{"web"}}},
+	}
+
+	want := []ContainerAddress{
+		{IP: "10.0.0.5", Name: "web", Domains: []string{"web"}},
+		{IP: "172.17.0.2", Name: "web", Domains: []string{"web"}},
+	}
+
+	for i := 0; i < 10; i++ {
+		got := flattenSorted(snapshot)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: expected deterministic order %v, got %v", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package hoster keeps /etc/hosts (or any other Publisher) in sync with the
+// containers running on a Docker or Podman daemon.
+package hoster
+
+// ContainerAddress is a single IP -> names mapping hoster publishes for a
+// container, scoped to one of its networks.
+type ContainerAddress struct {
+	IP      string
+	Name    string
+	Domains []string
+	// Subnet is the CIDR of the network this address belongs to (e.g.
+	// "172.17.0.0/16"), or empty if it couldn't be determined. Publishers
+	// that support per-network views, such as DNSPublisher, use it to
+	// answer a query only with the names reachable from the querying
+	// client's own network.
+	Subnet string
+}
+
+// EventAction identifies what a Watcher observed happen to a container.
+type EventAction string
+
+const (
+	// EventContainerUp means Addresses should be published: the container
+	// started, or its HEALTHCHECK recovered to healthy.
+	EventContainerUp EventAction = "up"
+	// EventContainerDown means any previously published addresses for
+	// ContainerID should be removed: the container stopped, was destroyed,
+	// became unhealthy, or hoster.enable was toggled to false.
+	EventContainerDown EventAction = "down"
+)
+
+// Event describes a single container address change observed by a Watcher.
+type Event struct {
+	Action      EventAction
+	ContainerID string
+	Addresses   []ContainerAddress
+}
@@ -0,0 +1,149 @@
+package hoster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+const (
+	initialEventsBackoff = 1 * time.Second
+	maxEventsBackoff     = 30 * time.Second
+)
+
+// Watcher turns Docker container lifecycle activity into a stream of
+// Events, decoupling Docker event handling from however those events end up
+// getting published.
+type Watcher struct {
+	client *Client
+}
+
+// NewWatcher builds a Watcher that observes containers through client.
+func NewWatcher(client *Client) *Watcher {
+	return &Watcher{client: client}
+}
+
+// Subscribe starts watching and returns a channel of Events plus a channel
+// of fatal errors. It first emits one Event for every container currently
+// running on the daemon (a container that fails to inspect is logged and
+// skipped), then reflects start/stop/health changes as they happen. Both channels are closed once ctx is done.
+func (w *Watcher) Subscribe(ctx context.Context) (<-chan Event, <-chan error) {
+	out := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+		defer close(errs)
+		w.run(ctx, out, errs)
+	}()
+
+	return out, errs
+}
+
+func (w *Watcher) run(ctx context.Context, out chan<- Event, errs chan<- error) {
+	containers, err := w.client.ListContainers(ctx)
+	if err != nil {
+		select {
+		case errs <- err:
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	for _, c := range containers {
+		w.emitContainer(ctx, c.ID, out)
+	}
+
+	w.watchEvents(ctx, out, errs)
+}
+
+func (w *Watcher) emitContainer(ctx context.Context, containerID string, out chan<- Event) {
+	addresses, err := w.client.ContainerAddresses(ctx, containerID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hoster: error inspecting container %s: %v\n", containerID, err)
+		return
+	}
+
+	action := EventContainerDown
+	if len(addresses) > 0 {
+		action = EventContainerUp
+	}
+
+	select {
+	case out <- Event{Action: action, ContainerID: containerID, Addresses: addresses}:
+	case <-ctx.Done():
+	}
+}
+
+// watchEvents subscribes to the Docker events stream and, if it breaks with
+// an error, resubscribes with exponential backoff instead of giving up. A
+// stream that ends cleanly with io.EOF is resubscribed at once, without
+// waiting, and resets the backoff. A local Unix socket rarely disconnects,
+// but a remote TCP or SSH daemon connection is far less reliable.
+func (w *Watcher) watchEvents(ctx context.Context, out chan<- Event, errs chan<- error) {
+	backoff := initialEventsBackoff
+
+	for {
+		eventsChan, errChan := w.client.Events(ctx)
+
+		err := w.consumeEvents(ctx, eventsChan, errChan, out)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			backoff = initialEventsBackoff
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "hoster: events stream dropped, reconnecting in %s: %v\n", backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxEventsBackoff {
+			backoff = maxEventsBackoff
+		}
+	}
+}
+
+// consumeEvents drains a single Events() subscription until it ends, either
+// because the context was cancelled (nil) or the stream broke (non-nil,
+// other than a clean io.EOF).
+func (w *Watcher) consumeEvents(ctx context.Context, eventsChan <-chan events.Message, errChan <-chan error, out chan<- Event) error {
+	for {
+		select {
+		case event := <-eventsChan:
+			if event.Type != events.ContainerEventType {
+				continue
+			}
+
+			switch event.Action {
+			case "start", "health_status: healthy", "health_status: unhealthy":
+				w.emitContainer(ctx, event.Actor.ID, out)
+
+			case "stop", "die", "destroy":
+				select {
+				case out <- Event{Action: EventContainerDown, ContainerID: event.Actor.ID}:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+
+		case err := <-errChan:
+			if err != nil && err != io.EOF {
+				return err
+			}
+			return nil
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}